@@ -0,0 +1,659 @@
+// Package nmap wraps the nmap binary, letting callers build up a scan
+// through functional options and run it as they would from the command
+// line, while getting back a parsed result instead of raw XML.
+package nmap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrScanTimeout is returned by Scanner.Run when the context passed via
+// WithContext is done before nmap exits on its own.
+var ErrScanTimeout = errors.New("nmap scan timed out")
+
+// Scanner is a nmap scan configuration, built up through functional
+// options and run via Run.
+type Scanner struct {
+	args       []string
+	binaryPath string
+	ctx        context.Context
+
+	hostFilters []func(Host) bool
+	portFilters []func(Port) bool
+
+	outputFormats []OutputFormat
+
+	resumeFile   string
+	resumeTTL    time.Duration
+	resumeTTLSet bool
+}
+
+// New creates a Scanner, applying every option in order. The binary path
+// is only resolved when Run is called, so New can be used to build up
+// arguments even in environments without nmap installed.
+func New(options ...func(*Scanner)) (*Scanner, error) {
+	scanner := &Scanner{
+		binaryPath: "nmap",
+	}
+
+	for _, option := range options {
+		option(scanner)
+	}
+
+	return scanner, nil
+}
+
+// Run runs nmap synchronously with the configured options and returns
+// the parsed result.
+func (s *Scanner) Run() (*Run, error) {
+	if len(s.outputFormats) > 0 {
+		return s.runWithOutputFormats()
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := s.commandWithOutput([]string{"-oX", "-"})
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if s.ctx != nil && s.ctx.Err() != nil {
+			return nil, ErrScanTimeout
+		}
+		return nil, fmt.Errorf("nmap scan failed: %s", err)
+	}
+
+	if stderr.Len() > 0 {
+		return nil, errors.New(stderr.String())
+	}
+
+	var result Run
+	if err := xml.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, err
+	}
+
+	result.Hosts = s.filterHosts(result.Hosts)
+	for i := range result.Hosts {
+		result.Hosts[i].Ports = s.filterPorts(result.Hosts[i].Ports)
+	}
+
+	return &result, nil
+}
+
+// commandWithOutput assembles the exec.Cmd for this scan, honoring the
+// context if one was set via WithContext. outputArgs is the -oX/-oA flag
+// and its value, and extraArgs are any further flags inserted ahead of
+// the scanner's own args, so callers can add flags without disturbing
+// target specifications appended by the user.
+func (s *Scanner) commandWithOutput(outputArgs []string, extraArgs ...string) *exec.Cmd {
+	args := append([]string{}, outputArgs...)
+	args = append(args, extraArgs...)
+	args = append(args, s.args...)
+
+	if s.ctx != nil {
+		return exec.CommandContext(s.ctx, s.binaryPath, args...)
+	}
+
+	return exec.Command(s.binaryPath, args...)
+}
+
+// filterHosts drops every Host that does not satisfy all of the filters
+// registered via WithFilterHost.
+func (s *Scanner) filterHosts(hosts []Host) []Host {
+	if len(s.hostFilters) == 0 {
+		return hosts
+	}
+
+	filtered := hosts[:0]
+	for _, host := range hosts {
+		if s.hostMatches(host) {
+			filtered = append(filtered, host)
+		}
+	}
+
+	return filtered
+}
+
+func (s *Scanner) hostMatches(host Host) bool {
+	for _, filter := range s.hostFilters {
+		if !filter(host) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// filterPorts drops every Port that does not satisfy all of the filters
+// registered via WithFilterPort.
+func (s *Scanner) filterPorts(ports []Port) []Port {
+	if len(s.portFilters) == 0 {
+		return ports
+	}
+
+	filtered := ports[:0]
+	for _, port := range ports {
+		if s.portMatches(port) {
+			filtered = append(filtered, port)
+		}
+	}
+
+	return filtered
+}
+
+func (s *Scanner) portMatches(port Port) bool {
+	for _, filter := range s.portFilters {
+		if !filter(port) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WithBinaryPath sets the path to the nmap binary to run. When unset,
+// "nmap" is resolved from the PATH when the scan is run.
+func WithBinaryPath(binaryPath string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.binaryPath = binaryPath
+	}
+}
+
+// WithContext sets the context used to run nmap. Canceling it kills the
+// running nmap process and causes Run to return ErrScanTimeout.
+func WithContext(ctx context.Context) func(*Scanner) {
+	return func(s *Scanner) {
+		s.ctx = ctx
+	}
+}
+
+// WithCustomArguments appends raw arguments to the nmap command line,
+// for flags this package does not expose a dedicated option for.
+func WithCustomArguments(args ...string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, args...)
+	}
+}
+
+// WithFilterHost registers a predicate that Run applies to every host in
+// the result; hosts for which it returns false are dropped. Multiple
+// filters registered this way are combined with a logical AND.
+func WithFilterHost(filter func(Host) bool) func(*Scanner) {
+	return func(s *Scanner) {
+		s.hostFilters = append(s.hostFilters, filter)
+	}
+}
+
+// WithFilterPort registers a predicate that Run applies to every port of
+// every host in the result; ports for which it returns false are
+// dropped. Multiple filters registered this way are combined with a
+// logical AND.
+func WithFilterPort(filter func(Port) bool) func(*Scanner) {
+	return func(s *Scanner) {
+		s.portFilters = append(s.portFilters, filter)
+	}
+}
+
+// Target specification.
+
+// WithTarget adds one or more targets to be scanned.
+func WithTarget(targets ...string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, targets...)
+	}
+}
+
+// WithTargetInput reads the targets to scan from a file.
+func WithTargetInput(file string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-iL", file)
+	}
+}
+
+// WithRandomTargets chooses a given number of random public IPs to scan.
+func WithRandomTargets(count int) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-iR", strconv.Itoa(count))
+	}
+}
+
+// WithTargetExclusion excludes the given hosts or networks from the scan.
+func WithTargetExclusion(targets string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--exclude", targets)
+	}
+}
+
+// WithTargetExclusionInput excludes the hosts or networks listed in the
+// given file from the scan.
+func WithTargetExclusionInput(file string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--excludefile", file)
+	}
+}
+
+// Host discovery.
+
+// WithListScan lists the targets to scan without actually scanning them.
+func WithListScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sL")
+	}
+}
+
+// WithPingScan disables port scanning and only discovers hosts that are
+// up.
+func WithPingScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sn")
+	}
+}
+
+// WithSkipHostDiscovery treats every target as online, skipping host
+// discovery.
+func WithSkipHostDiscovery() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-Pn")
+	}
+}
+
+// WithSYNDiscovery uses TCP SYN packets for host discovery, optionally
+// against the given ports.
+func WithSYNDiscovery(ports string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-PS"+ports)
+	}
+}
+
+// WithACKDiscovery uses TCP ACK packets for host discovery, optionally
+// against the given ports.
+func WithACKDiscovery(ports string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-PA"+ports)
+	}
+}
+
+// WithUDPDiscovery uses UDP packets for host discovery, optionally
+// against the given ports.
+func WithUDPDiscovery(ports string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-PU"+ports)
+	}
+}
+
+// WithSCTPDiscovery uses SCTP INIT packets for host discovery, optionally
+// against the given ports.
+func WithSCTPDiscovery(ports string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-PY"+ports)
+	}
+}
+
+// WithICMPEchoDiscovery uses ICMP echo request packets for host
+// discovery.
+func WithICMPEchoDiscovery() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-PE")
+	}
+}
+
+// WithICMPTimestampDiscovery uses ICMP timestamp request packets for host
+// discovery.
+func WithICMPTimestampDiscovery() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-PP")
+	}
+}
+
+// WithICMPNetMaskDiscovery uses ICMP netmask request packets for host
+// discovery.
+func WithICMPNetMaskDiscovery() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-PM")
+	}
+}
+
+// WithIPProtocolPingDiscovery sends IP packets with the given protocols
+// set for host discovery.
+func WithIPProtocolPingDiscovery(protocols string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-PO"+protocols)
+	}
+}
+
+// WithDisabledDNSResolution never does reverse DNS resolution on the
+// active targets.
+func WithDisabledDNSResolution() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-n")
+	}
+}
+
+// WithForcedDNSResolution always does reverse DNS resolution on the
+// targets.
+func WithForcedDNSResolution() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-R")
+	}
+}
+
+// WithCustomDNSServers uses the given comma-separated DNS servers instead
+// of the system's configured ones.
+func WithCustomDNSServers(servers string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--dns-servers", servers)
+	}
+}
+
+// WithSystemDNS uses the OS's DNS resolver instead of nmap's own.
+func WithSystemDNS() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--system-dns")
+	}
+}
+
+// WithTraceRoute traces the network path to each discovered host.
+func WithTraceRoute() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--traceroute")
+	}
+}
+
+// Scan techniques.
+
+// WithSYNScan uses TCP SYN scanning, nmap's default and most popular
+// scan type.
+func WithSYNScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sS")
+	}
+}
+
+// WithConnectScan uses TCP connect() scanning, for when SYN scanning is
+// not available (e.g. no raw socket privileges).
+func WithConnectScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sT")
+	}
+}
+
+// WithACKScan uses TCP ACK scanning, typically to map firewall rulesets.
+func WithACKScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sA")
+	}
+}
+
+// WithWindowScan uses TCP Window scanning, a variant of ACK scanning.
+func WithWindowScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sW")
+	}
+}
+
+// WithMaimonScan uses the Maimon scan technique.
+func WithMaimonScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sM")
+	}
+}
+
+// WithUDPScan uses UDP scanning.
+func WithUDPScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sU")
+	}
+}
+
+// WithTCPNullScan uses TCP Null scanning, sending packets with no flags
+// set.
+func WithTCPNullScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sN")
+	}
+}
+
+// WithTCPFINScan uses TCP FIN scanning.
+func WithTCPFINScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sF")
+	}
+}
+
+// WithTCPXmasScan uses TCP Xmas scanning, setting the FIN, PSH and URG
+// flags.
+func WithTCPXmasScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sX")
+	}
+}
+
+// ScanFlag is a single TCP flag bit, used to build a custom flag
+// combination for WithTCPScanFlags.
+type ScanFlag int
+
+// TCP flags usable with WithTCPScanFlags, matching their bit position in
+// the TCP header.
+const (
+	FlagNULL ScanFlag = 0
+	FlagFIN  ScanFlag = 1
+	FlagSYN  ScanFlag = 1 << 1
+	FlagRST  ScanFlag = 1 << 2
+	FlagPSH  ScanFlag = 1 << 3
+	FlagACK  ScanFlag = 1 << 4
+	FlagURG  ScanFlag = 1 << 5
+	FlagECE  ScanFlag = 1 << 6
+	FlagCWR  ScanFlag = 1 << 7
+)
+
+// WithTCPScanFlags uses a custom combination of TCP flags for scanning,
+// via nmap's --scanflags.
+func WithTCPScanFlags(flags ...ScanFlag) func(*Scanner) {
+	return func(s *Scanner) {
+		var combined ScanFlag
+		for _, flag := range flags {
+			combined |= flag
+		}
+
+		s.args = append(s.args, "--scanflags", strconv.FormatInt(int64(combined), 16))
+	}
+}
+
+// WithIdleScan uses a zombie host to perform an idle scan, optionally
+// through a specific probe port on that zombie.
+func WithIdleScan(zombieHost string, probePort int) func(*Scanner) {
+	return func(s *Scanner) {
+		if probePort != 0 {
+			s.args = append(s.args, "-sI", fmt.Sprintf("%s:%d", zombieHost, probePort))
+			return
+		}
+
+		s.args = append(s.args, "-sI", zombieHost)
+	}
+}
+
+// WithSCTPInitScan uses SCTP INIT scanning.
+func WithSCTPInitScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sY")
+	}
+}
+
+// WithSCTPCookieEchoScan uses SCTP COOKIE-ECHO scanning.
+func WithSCTPCookieEchoScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sZ")
+	}
+}
+
+// WithIPProtocolScan determines which IP protocols are supported by the
+// target hosts.
+func WithIPProtocolScan() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sO")
+	}
+}
+
+// WithFTPBounceScan checks whether the given FTP server can be used to
+// scan other hosts.
+func WithFTPBounceScan(host string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-b", host)
+	}
+}
+
+// Port specification and scan order.
+
+// WithPorts restricts the scan to the given ports or port ranges.
+func WithPorts(ports ...string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-p", strings.Join(ports, ","))
+	}
+}
+
+// WithPortExclusions excludes the given ports or port ranges from the
+// scan.
+func WithPortExclusions(ports string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--exclude-ports", ports)
+	}
+}
+
+// WithFastMode scans fewer ports than the default scan.
+func WithFastMode() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-F")
+	}
+}
+
+// WithConsecutivePortScanning scans ports in order instead of randomizing
+// them.
+func WithConsecutivePortScanning() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-r")
+	}
+}
+
+// WithMostCommonPorts scans the given number of most commonly open ports.
+func WithMostCommonPorts(count int) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--top-ports", strconv.Itoa(count))
+	}
+}
+
+// WithPortRatio scans ports at least as common as the given ratio, which
+// must be between 0 and 1. It panics otherwise, since it indicates a
+// programming error rather than something the caller can recover from.
+func WithPortRatio(ratio float32) func(*Scanner) {
+	return func(s *Scanner) {
+		if ratio < 0 || ratio > 1 {
+			panic("value given to nmap.WithPortRatio() should be between 0 and 1")
+		}
+
+		s.args = append(s.args, "--port-ratio", fmt.Sprintf("%.1f", ratio))
+	}
+}
+
+// Service/version detection.
+
+// WithServiceInfo probes open ports to determine service/version info.
+func WithServiceInfo() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sV")
+	}
+}
+
+// WithVersionIntensity sets the intensity of version detection, between
+// 0 and 9. It panics otherwise, since it indicates a programming error
+// rather than something the caller can recover from.
+func WithVersionIntensity(intensity int) func(*Scanner) {
+	return func(s *Scanner) {
+		if intensity < 0 || intensity > 9 {
+			panic("value given to nmap.WithVersionIntensity() should be between 0 and 9")
+		}
+
+		s.args = append(s.args, "--version-intensity", strconv.Itoa(intensity))
+	}
+}
+
+// WithVersionLight sets a low intensity, enabling faster version
+// detection.
+func WithVersionLight() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--version-light")
+	}
+}
+
+// WithVersionAll tries every single version detection probe against each
+// port.
+func WithVersionAll() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--version-all")
+	}
+}
+
+// WithVersionTrace shows detailed version scan activity for debugging.
+func WithVersionTrace() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--version-trace")
+	}
+}
+
+// Script scan.
+
+// WithDefaultScript runs nmap's default set of NSE scripts.
+func WithDefaultScript() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-sC")
+	}
+}
+
+// WithScripts runs the NSE scripts found in the given comma-separated
+// list of files, directories or script categories.
+func WithScripts(scripts string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--script="+scripts)
+	}
+}
+
+// WithScriptArguments passes the given arguments to the NSE scripts that
+// run as part of the scan.
+func WithScriptArguments(arguments map[string]string) func(*Scanner) {
+	return func(s *Scanner) {
+		pairs := make([]string, 0, len(arguments))
+		for key, value := range arguments {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+		}
+
+		s.args = append(s.args, "--script-args="+strings.Join(pairs, ","))
+	}
+}
+
+// WithScriptArgumentsFile reads NSE script arguments from the given file.
+func WithScriptArgumentsFile(file string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--script-args-file="+file)
+	}
+}
+
+// WithScriptTrace shows all data sent and received by NSE scripts.
+func WithScriptTrace() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--script-trace")
+	}
+}
+
+// WithScriptUpdateDB updates the NSE script database before scanning.
+func WithScriptUpdateDB() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--script-updatedb")
+	}
+}
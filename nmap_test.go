@@ -991,177 +991,529 @@ func TestScriptScan(t *testing.T) {
 	}
 }
 
-// func TestOSDetection(t *testing.T) {
-// 	tests := []struct {
-// 		description string
-
-// 		options []func(*Scanner)
-
-// 		expectedArgs []string
-// 	}{
-// 		{
-// 			description: "",
-
-// 			options: []func(*Scanner){
-// 				WithXXX(),
-// 			},
-
-// 			expectedArgs: []string{
-// 				"--xxx",
-// 			},
-// 		},
-// 	}
-
-// 	for _, test := range tests {
-// 		t.Run(test.description, func(t *testing.T) {
-// 			s, err := New(test.options...)
-// 			if err != nil {
-// 				panic(err)
-// 			}
-
-// 			if !reflect.DeepEqual(s.args, test.expectedArgs) {
-// 				t.Errorf("unexpected arguments, expected %s got %s", test.expectedArgs, s.args)
-// 			}
-// 		})
-// 	}
-// }
-
-// func TestTimingAndPerformance(t *testing.T) {
-// 	tests := []struct {
-// 		description string
-
-// 		options []func(*Scanner)
-
-// 		expectedArgs []string
-// 	}{
-// 		{
-// 			description: "",
-
-// 			options: []func(*Scanner){
-// 				WithXXX(),
-// 			},
-
-// 			expectedArgs: []string{
-// 				"--xxx",
-// 			},
-// 		},
-// 	}
-
-// 	for _, test := range tests {
-// 		t.Run(test.description, func(t *testing.T) {
-// 			s, err := New(test.options...)
-// 			if err != nil {
-// 				panic(err)
-// 			}
-
-// 			if !reflect.DeepEqual(s.args, test.expectedArgs) {
-// 				t.Errorf("unexpected arguments, expected %s got %s", test.expectedArgs, s.args)
-// 			}
-// 		})
-// 	}
-// }
-
-// func TestFirewallAndIDSEvasionAndSpoofing(t *testing.T) {
-// 	tests := []struct {
-// 		description string
-
-// 		options []func(*Scanner)
-
-// 		expectedArgs []string
-// 	}{
-// 		{
-// 			description: "",
-
-// 			options: []func(*Scanner){
-// 				WithXXX(),
-// 			},
-
-// 			expectedArgs: []string{
-// 				"--xxx",
-// 			},
-// 		},
-// 	}
-
-// 	for _, test := range tests {
-// 		t.Run(test.description, func(t *testing.T) {
-// 			s, err := New(test.options...)
-// 			if err != nil {
-// 				panic(err)
-// 			}
-
-// 			if !reflect.DeepEqual(s.args, test.expectedArgs) {
-// 				t.Errorf("unexpected arguments, expected %s got %s", test.expectedArgs, s.args)
-// 			}
-// 		})
-// 	}
-// }
-
-// func TestOutput(t *testing.T) {
-// 	tests := []struct {
-// 		description string
-
-// 		options []func(*Scanner)
-
-// 		expectedArgs []string
-// 	}{
-// 		{
-// 			description: "",
-
-// 			options: []func(*Scanner){
-// 				WithXXX(),
-// 			},
-
-// 			expectedArgs: []string{
-// 				"--xxx",
-// 			},
-// 		},
-// 	}
-
-// 	for _, test := range tests {
-// 		t.Run(test.description, func(t *testing.T) {
-// 			s, err := New(test.options...)
-// 			if err != nil {
-// 				panic(err)
-// 			}
-
-// 			if !reflect.DeepEqual(s.args, test.expectedArgs) {
-// 				t.Errorf("unexpected arguments, expected %s got %s", test.expectedArgs, s.args)
-// 			}
-// 		})
-// 	}
-// }
-
-// func TestMiscellaneous(t *testing.T) {
-// 	tests := []struct {
-// 		description string
-
-// 		options []func(*Scanner)
-
-// 		expectedArgs []string
-// 	}{
-// 		{
-// 			description: "",
-
-// 			options: []func(*Scanner){
-// 				WithXXX(),
-// 			},
-
-// 			expectedArgs: []string{
-// 				"--xxx",
-// 			},
-// 		},
-// 	}
-
-// 	for _, test := range tests {
-// 		t.Run(test.description, func(t *testing.T) {
-// 			s, err := New(test.options...)
-// 			if err != nil {
-// 				panic(err)
-// 			}
-
-// 			if !reflect.DeepEqual(s.args, test.expectedArgs) {
-// 				t.Errorf("unexpected arguments, expected %s got %s", test.expectedArgs, s.args)
-// 			}
-// 		})
-// 	}
-// }
+func TestOSDetection(t *testing.T) {
+	tests := []struct {
+		description string
+
+		options []func(*Scanner)
+
+		expectedArgs []string
+	}{
+		{
+			description: "enable OS detection",
+
+			options: []func(*Scanner){
+				WithOSDetection(),
+			},
+
+			expectedArgs: []string{
+				"-O",
+			},
+		},
+		{
+			description: "limit OS detection to promising hosts",
+
+			options: []func(*Scanner){
+				WithOSScanLimit(),
+			},
+
+			expectedArgs: []string{
+				"--osscan-limit",
+			},
+		},
+		{
+			description: "guess OS matches more aggressively",
+
+			options: []func(*Scanner){
+				WithOSScanGuess(),
+			},
+
+			expectedArgs: []string{
+				"--osscan-guess",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			s, err := New(test.options...)
+			if err != nil {
+				panic(err)
+			}
+
+			if !reflect.DeepEqual(s.args, test.expectedArgs) {
+				t.Errorf("unexpected arguments, expected %s got %s", test.expectedArgs, s.args)
+			}
+		})
+	}
+}
+
+func TestTimingAndPerformance(t *testing.T) {
+	tests := []struct {
+		description string
+
+		options []func(*Scanner)
+
+		expectedArgs []string
+	}{
+		{
+			description: "paranoid timing template",
+
+			options: []func(*Scanner){
+				WithTimingTemplate(T0),
+			},
+
+			expectedArgs: []string{
+				"-T0",
+			},
+		},
+		{
+			description: "aggressive timing template",
+
+			options: []func(*Scanner){
+				WithTimingTemplate(T4),
+			},
+
+			expectedArgs: []string{
+				"-T4",
+			},
+		},
+		{
+			description: "minimum hostgroup size",
+
+			options: []func(*Scanner){
+				WithMinHostgroup(4),
+			},
+
+			expectedArgs: []string{
+				"--min-hostgroup",
+				"4",
+			},
+		},
+		{
+			description: "maximum hostgroup size",
+
+			options: []func(*Scanner){
+				WithMaxHostgroup(32),
+			},
+
+			expectedArgs: []string{
+				"--max-hostgroup",
+				"32",
+			},
+		},
+		{
+			description: "minimum parallelism",
+
+			options: []func(*Scanner){
+				WithMinParallelism(1),
+			},
+
+			expectedArgs: []string{
+				"--min-parallelism",
+				"1",
+			},
+		},
+		{
+			description: "maximum parallelism",
+
+			options: []func(*Scanner){
+				WithMaxParallelism(10),
+			},
+
+			expectedArgs: []string{
+				"--max-parallelism",
+				"10",
+			},
+		},
+		{
+			description: "minimum RTT timeout",
+
+			options: []func(*Scanner){
+				WithMinRTTTimeout(100 * time.Millisecond),
+			},
+
+			expectedArgs: []string{
+				"--min-rtt-timeout",
+				"100ms",
+			},
+		},
+		{
+			description: "maximum RTT timeout",
+
+			options: []func(*Scanner){
+				WithMaxRTTTimeout(500 * time.Millisecond),
+			},
+
+			expectedArgs: []string{
+				"--max-rtt-timeout",
+				"500ms",
+			},
+		},
+		{
+			description: "maximum retries",
+
+			options: []func(*Scanner){
+				WithMaxRetries(2),
+			},
+
+			expectedArgs: []string{
+				"--max-retries",
+				"2",
+			},
+		},
+		{
+			description: "host timeout",
+
+			options: []func(*Scanner){
+				WithHostTimeout(30 * time.Minute),
+			},
+
+			expectedArgs: []string{
+				"--host-timeout",
+				"1800000ms",
+			},
+		},
+		{
+			description: "scan delay",
+
+			options: []func(*Scanner){
+				WithScanDelay(1 * time.Second),
+			},
+
+			expectedArgs: []string{
+				"--scan-delay",
+				"1000ms",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			s, err := New(test.options...)
+			if err != nil {
+				panic(err)
+			}
+
+			if !reflect.DeepEqual(s.args, test.expectedArgs) {
+				t.Errorf("unexpected arguments, expected %s got %s", test.expectedArgs, s.args)
+			}
+		})
+	}
+}
+
+func TestFirewallAndIDSEvasionAndSpoofing(t *testing.T) {
+	tests := []struct {
+		description string
+
+		options []func(*Scanner)
+
+		expectedArgs []string
+	}{
+		{
+			description: "fragment packets",
+
+			options: []func(*Scanner){
+				WithFragmentPackets(),
+			},
+
+			expectedArgs: []string{
+				"-f",
+			},
+		},
+		{
+			description: "custom MTU",
+
+			options: []func(*Scanner){
+				WithMTU(24),
+			},
+
+			expectedArgs: []string{
+				"--mtu",
+				"24",
+			},
+		},
+		{
+			description: "scan with decoys",
+
+			options: []func(*Scanner){
+				WithDecoys("192.168.1.1", "192.168.1.2"),
+			},
+
+			expectedArgs: []string{
+				"-D",
+				"192.168.1.1,192.168.1.2",
+			},
+		},
+		{
+			description: "spoof source address",
+
+			options: []func(*Scanner){
+				WithSpoofIPAddress("192.168.1.1"),
+			},
+
+			expectedArgs: []string{
+				"-S",
+				"192.168.1.1",
+			},
+		},
+		{
+			description: "spoof source MAC address",
+
+			options: []func(*Scanner){
+				WithSpoofMAC("08:00:27:00:00:00"),
+			},
+
+			expectedArgs: []string{
+				"--spoof-mac",
+				"08:00:27:00:00:00",
+			},
+		},
+		{
+			description: "send packets with a bogus checksum",
+
+			options: []func(*Scanner){
+				WithBadSum(),
+			},
+
+			expectedArgs: []string{
+				"--badsum",
+			},
+		},
+		{
+			description: "append a custom data string to probes",
+
+			options: []func(*Scanner){
+				WithDataString("deadbeef"),
+			},
+
+			expectedArgs: []string{
+				"--data-string",
+				"deadbeef",
+			},
+		},
+		{
+			description: "append custom hex data to probes",
+
+			options: []func(*Scanner){
+				WithHexData("0xdeadbeef"),
+			},
+
+			expectedArgs: []string{
+				"--data",
+				"0xdeadbeef",
+			},
+		},
+		{
+			description: "append random data of a given length to probes",
+
+			options: []func(*Scanner){
+				WithDataLength(25),
+			},
+
+			expectedArgs: []string{
+				"--data-length",
+				"25",
+			},
+		},
+		{
+			description: "relay connections through a chain of proxies",
+
+			options: []func(*Scanner){
+				WithProxies("socks4://localhost:1080"),
+			},
+
+			expectedArgs: []string{
+				"--proxies",
+				"socks4://localhost:1080",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			s, err := New(test.options...)
+			if err != nil {
+				panic(err)
+			}
+
+			if !reflect.DeepEqual(s.args, test.expectedArgs) {
+				t.Errorf("unexpected arguments, expected %s got %s", test.expectedArgs, s.args)
+			}
+		})
+	}
+}
+
+func TestOutput(t *testing.T) {
+	tests := []struct {
+		description string
+
+		options []func(*Scanner)
+
+		expectedArgs []string
+	}{
+		{
+			description: "write normal-format output to a file",
+
+			options: []func(*Scanner){
+				WithNmapOutput("/tmp/scan.nmap"),
+			},
+
+			expectedArgs: []string{
+				"-oN",
+				"/tmp/scan.nmap",
+			},
+		},
+		{
+			description: "increase verbosity",
+
+			options: []func(*Scanner){
+				WithVerbosity(2),
+			},
+
+			expectedArgs: []string{
+				"-v",
+				"-v",
+			},
+		},
+		{
+			description: "increase debugging level",
+
+			options: []func(*Scanner){
+				WithDebugging(3),
+			},
+
+			expectedArgs: []string{
+				"-d",
+				"-d",
+				"-d",
+			},
+		},
+		{
+			description: "show reason for port and host states",
+
+			options: []func(*Scanner){
+				WithReason(),
+			},
+
+			expectedArgs: []string{
+				"--reason",
+			},
+		},
+		{
+			description: "only show open ports",
+
+			options: []func(*Scanner){
+				WithOpenOnly(),
+			},
+
+			expectedArgs: []string{
+				"--open",
+			},
+		},
+		{
+			description: "trace every packet sent and received",
+
+			options: []func(*Scanner){
+				WithPacketTrace(),
+			},
+
+			expectedArgs: []string{
+				"--packet-trace",
+			},
+		},
+		{
+			description: "resume a previously aborted scan",
+
+			options: []func(*Scanner){
+				WithResumePreviousScan("/tmp/scan.nmap"),
+			},
+
+			expectedArgs: []string{
+				"--resume",
+				"/tmp/scan.nmap",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			s, err := New(test.options...)
+			if err != nil {
+				panic(err)
+			}
+
+			if !reflect.DeepEqual(s.args, test.expectedArgs) {
+				t.Errorf("unexpected arguments, expected %s got %s", test.expectedArgs, s.args)
+			}
+		})
+	}
+}
+
+func TestMiscellaneous(t *testing.T) {
+	tests := []struct {
+		description string
+
+		options []func(*Scanner)
+
+		expectedArgs []string
+	}{
+		{
+			description: "assume privileged, raw socket access",
+
+			options: []func(*Scanner){
+				WithPrivileged(),
+			},
+
+			expectedArgs: []string{
+				"--privileged",
+			},
+		},
+		{
+			description: "assume unprivileged, no raw socket access",
+
+			options: []func(*Scanner){
+				WithUnprivileged(),
+			},
+
+			expectedArgs: []string{
+				"--unprivileged",
+			},
+		},
+		{
+			description: "send packets at the raw Ethernet layer",
+
+			options: []func(*Scanner){
+				WithSendEth(),
+			},
+
+			expectedArgs: []string{
+				"--send-eth",
+			},
+		},
+		{
+			description: "send packets through raw IP sockets",
+
+			options: []func(*Scanner){
+				WithSendIP(),
+			},
+
+			expectedArgs: []string{
+				"--send-ip",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			s, err := New(test.options...)
+			if err != nil {
+				panic(err)
+			}
+
+			if !reflect.DeepEqual(s.args, test.expectedArgs) {
+				t.Errorf("unexpected arguments, expected %s got %s", test.expectedArgs, s.args)
+			}
+		})
+	}
+}
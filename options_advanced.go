@@ -0,0 +1,305 @@
+package nmap
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OS detection.
+
+// WithOSDetection enables OS detection (-O).
+func WithOSDetection() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-O")
+	}
+}
+
+// WithOSScanLimit only attempts OS detection against hosts that have at
+// least one open and one closed port.
+func WithOSScanLimit() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--osscan-limit")
+	}
+}
+
+// WithOSScanGuess makes OS detection guess more aggressively, reporting
+// close matches as well as near-certain ones.
+func WithOSScanGuess() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--osscan-guess")
+	}
+}
+
+// Timing and performance.
+
+// Timing is one of nmap's six timing templates, trading scan speed for
+// stealth and reliability. Use it with WithTimingTemplate.
+type Timing int
+
+// Timing templates, from the most cautious (T0) to the most aggressive
+// (T5).
+const (
+	T0 Timing = iota
+	T1
+	T2
+	T3
+	T4
+	T5
+)
+
+// WithTimingTemplate sets one of nmap's predefined timing templates
+// (-T0 through -T5).
+func WithTimingTemplate(timing Timing) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-T"+strconv.Itoa(int(timing)))
+	}
+}
+
+// WithMinHostgroup sets the minimum size of the group of hosts nmap
+// scans in parallel.
+func WithMinHostgroup(size int) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--min-hostgroup", strconv.Itoa(size))
+	}
+}
+
+// WithMaxHostgroup sets the maximum size of the group of hosts nmap
+// scans in parallel.
+func WithMaxHostgroup(size int) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--max-hostgroup", strconv.Itoa(size))
+	}
+}
+
+// WithMinParallelism sets the minimum number of probes nmap runs in
+// parallel.
+func WithMinParallelism(probes int) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--min-parallelism", strconv.Itoa(probes))
+	}
+}
+
+// WithMaxParallelism sets the maximum number of probes nmap runs in
+// parallel.
+func WithMaxParallelism(probes int) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--max-parallelism", strconv.Itoa(probes))
+	}
+}
+
+// WithMinRTTTimeout sets the minimum probe round-trip-time timeout.
+func WithMinRTTTimeout(timeout time.Duration) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--min-rtt-timeout", durationArg(timeout))
+	}
+}
+
+// WithMaxRTTTimeout sets the maximum probe round-trip-time timeout.
+func WithMaxRTTTimeout(timeout time.Duration) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--max-rtt-timeout", durationArg(timeout))
+	}
+}
+
+// WithMaxRetries caps the number of port scan probe retransmissions.
+func WithMaxRetries(retries int) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--max-retries", strconv.Itoa(retries))
+	}
+}
+
+// WithHostTimeout gives up on a host after the given duration.
+func WithHostTimeout(timeout time.Duration) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--host-timeout", durationArg(timeout))
+	}
+}
+
+// WithScanDelay waits at least the given duration between probes sent to
+// a host.
+func WithScanDelay(delay time.Duration) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--scan-delay", durationArg(delay))
+	}
+}
+
+// durationArg formats a duration the way nmap's time-spec arguments
+// expect: a single number followed by a unit, rather than Go's compound
+// representation (e.g. "30m0s").
+func durationArg(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+}
+
+// Firewall/IDS evasion and spoofing.
+
+// WithFragmentPackets splits probe packets into tiny fragments, making
+// them harder for firewalls and IDSes to inspect.
+func WithFragmentPackets() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-f")
+	}
+}
+
+// WithMTU sets a custom packet fragment size (must be a multiple of 8).
+func WithMTU(mtu int) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--mtu", strconv.Itoa(mtu))
+	}
+}
+
+// WithDecoys scans alongside spoofed decoy addresses, so the scan cannot
+// be easily traced back to a single source.
+func WithDecoys(decoys ...string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-D", strings.Join(decoys, ","))
+	}
+}
+
+// WithSpoofIPAddress spoofs the source address of the scan.
+func WithSpoofIPAddress(address string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-S", address)
+	}
+}
+
+// WithSpoofMAC spoofs the source MAC address of the scan.
+func WithSpoofMAC(mac string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--spoof-mac", mac)
+	}
+}
+
+// WithBadSum sends packets with an invalid TCP/UDP checksum, so that any
+// response received must come from a naive packet filter rather than a
+// real stack.
+func WithBadSum() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--badsum")
+	}
+}
+
+// WithDataString appends the given string to every probe packet sent.
+func WithDataString(data string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--data-string", data)
+	}
+}
+
+// WithHexData appends the given hex-encoded bytes to every probe packet
+// sent.
+func WithHexData(data string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--data", data)
+	}
+}
+
+// WithDataLength appends the given number of random bytes to most of the
+// packets nmap sends.
+func WithDataLength(length int) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--data-length", strconv.Itoa(length))
+	}
+}
+
+// WithProxies relays the scan's TCP connections through the given
+// comma-separated chain of proxies.
+func WithProxies(proxies string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--proxies", proxies)
+	}
+}
+
+// Output.
+
+// WithNmapOutput additionally writes nmap's normal-format output to the
+// given file (-oN), alongside whatever Run itself parses.
+func WithNmapOutput(path string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "-oN", path)
+	}
+}
+
+// WithVerbosity raises nmap's verbosity by the given number of levels
+// (each level stacks another -v, as nmap itself expects).
+func WithVerbosity(level int) func(*Scanner) {
+	return func(s *Scanner) {
+		for i := 0; i < level; i++ {
+			s.args = append(s.args, "-v")
+		}
+	}
+}
+
+// WithDebugging raises nmap's debugging level by the given number of
+// levels (each level stacks another -d, as nmap itself expects).
+func WithDebugging(level int) func(*Scanner) {
+	return func(s *Scanner) {
+		for i := 0; i < level; i++ {
+			s.args = append(s.args, "-d")
+		}
+	}
+}
+
+// WithReason shows the reason each port and host was assigned its
+// reported state.
+func WithReason() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--reason")
+	}
+}
+
+// WithOpenOnly only shows open (or possibly open) ports in the result.
+func WithOpenOnly() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--open")
+	}
+}
+
+// WithPacketTrace shows every packet sent and received by nmap.
+func WithPacketTrace() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--packet-trace")
+	}
+}
+
+// WithResumePreviousScan resumes a previously aborted scan from the
+// given normal-format output file.
+func WithResumePreviousScan(file string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--resume", file)
+	}
+}
+
+// Miscellaneous.
+
+// WithPrivileged assumes the scanner has raw socket privileges, skipping
+// nmap's own privilege detection.
+func WithPrivileged() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--privileged")
+	}
+}
+
+// WithUnprivileged assumes the scanner lacks raw socket privileges,
+// restricting it to techniques that work from an unprivileged socket.
+func WithUnprivileged() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--unprivileged")
+	}
+}
+
+// WithSendEth sends packets at the raw Ethernet (link) layer rather than
+// through raw IP sockets.
+func WithSendEth() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--send-eth")
+	}
+}
+
+// WithSendIP sends packets through raw IP sockets rather than at the
+// Ethernet (link) layer.
+func WithSendIP() func(*Scanner) {
+	return func(s *Scanner) {
+		s.args = append(s.args, "--send-ip")
+	}
+}
@@ -0,0 +1,155 @@
+package nmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ileansys/nmap/parser"
+)
+
+// OutputFormat is implemented by every additional nmap output format
+// that can be requested via WithOutputFormats.
+type OutputFormat interface {
+	extension() string
+}
+
+// XMLOutput requests nmap's XML output (-oX), which Run always parses
+// regardless of whether it is requested here.
+type XMLOutput struct{}
+
+func (XMLOutput) extension() string { return "xml" }
+
+// GrepableOutput requests nmap's grepable output (-oG), exposed on
+// Run.Grepable once parsed.
+type GrepableOutput struct{}
+
+func (GrepableOutput) extension() string { return "gnmap" }
+
+// NormalOutput requests nmap's normal output (-oN), exposed as raw text
+// on Run.Normal, since that format is meant for human eyes rather than
+// for structured parsing.
+type NormalOutput struct{}
+
+func (NormalOutput) extension() string { return "nmap" }
+
+// JSONOutput requests a JSON rendering of the scan result, synthesized
+// from the parsed XML output and exposed on Run.JSON. Unlike the other
+// formats, nmap does not produce this itself.
+type JSONOutput struct{}
+
+func (JSONOutput) extension() string { return "json" }
+
+// WithOutputFormats requests one or more additional output formats be
+// parsed and attached to the Run returned by Run, on top of the XML
+// output that is always parsed. Internally, the scan is invoked with
+// -oA so that every format is available to read back.
+func WithOutputFormats(formats ...OutputFormat) func(*Scanner) {
+	return func(s *Scanner) {
+		s.outputFormats = formats
+	}
+}
+
+// wantsOutputFormat reports whether a format of the same type as target
+// was requested via WithOutputFormats.
+func (s *Scanner) wantsOutputFormat(target OutputFormat) bool {
+	for _, format := range s.outputFormats {
+		if format.extension() == target.extension() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runWithOutputFormats runs nmap with -oA into a temporary directory,
+// then reads back and parses whichever formats were requested via
+// WithOutputFormats, attaching them to the XML-parsed Run.
+func (s *Scanner) runWithOutputFormats() (*Run, error) {
+	tmpDir, err := os.MkdirTemp("", "nmap")
+	if err != nil {
+		return nil, fmt.Errorf("nmap scan failed: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	base := filepath.Join(tmpDir, "scan")
+
+	var stdout, stderr bytes.Buffer
+	cmd := s.commandWithOutput([]string{"-oA", base})
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if s.ctx != nil && s.ctx.Err() != nil {
+			return nil, ErrScanTimeout
+		}
+		return nil, fmt.Errorf("nmap scan failed: %s", err)
+	}
+
+	if stderr.Len() > 0 {
+		return nil, errors.New(stderr.String())
+	}
+
+	result, err := s.parseOutputFormats(base)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// parseOutputFormats reads and parses the files nmap wrote via -oA at
+// base, attaching whichever ones were requested via WithOutputFormats.
+func (s *Scanner) parseOutputFormats(base string) (*Run, error) {
+	xmlFile, err := os.Open(base + ".xml")
+	if err != nil {
+		return nil, err
+	}
+	defer xmlFile.Close()
+
+	var result Run
+	if err := parser.XML(xmlFile, &result); err != nil {
+		return nil, err
+	}
+
+	result.Hosts = s.filterHosts(result.Hosts)
+	for i := range result.Hosts {
+		result.Hosts[i].Ports = s.filterPorts(result.Hosts[i].Ports)
+	}
+
+	if s.wantsOutputFormat(GrepableOutput{}) {
+		grepFile, err := os.Open(base + ".gnmap")
+		if err != nil {
+			return nil, err
+		}
+		defer grepFile.Close()
+
+		result.Grepable, err = parser.ParseGrepable(grepFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.wantsOutputFormat(NormalOutput{}) {
+		normal, err := os.ReadFile(base + ".nmap")
+		if err != nil {
+			return nil, err
+		}
+
+		result.Normal = string(normal)
+	}
+
+	if s.wantsOutputFormat(JSONOutput{}) {
+		encoded, err := json.Marshal(&result)
+		if err != nil {
+			return nil, err
+		}
+
+		result.JSON = encoded
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,139 @@
+package nmap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeNmapOutputFormats writes an executable shell script standing in
+// for nmap, which writes canned -oA files at the base path it's given
+// instead of actually scanning anything.
+func fakeNmapOutputFormats(t *testing.T, writeGrepable, writeNormal bool) string {
+	t.Helper()
+
+	script := `#!/bin/sh
+base="$2"
+cat > "$base.xml" <<'XML'
+<?xml version="1.0"?>
+<nmaprun scanner="nmap">
+<host><status state="up"/><address addr="10.0.0.1" addrtype="ipv4"/></host>
+</nmaprun>
+XML
+`
+	if writeGrepable {
+		script += `cat > "$base.gnmap" <<'GNMAP'
+Host: 10.0.0.1 ()	Status: Up
+GNMAP
+`
+	}
+	if writeNormal {
+		script += `cat > "$base.nmap" <<'NORMAL'
+Nmap scan report for 10.0.0.1
+NORMAL
+`
+	}
+
+	path := filepath.Join(t.TempDir(), "fake-nmap.sh")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake nmap script: %s", err)
+	}
+
+	return path
+}
+
+func TestWantsOutputFormat(t *testing.T) {
+	s, err := New(WithOutputFormats(GrepableOutput{}, JSONOutput{}))
+	if err != nil {
+		panic(err)
+	}
+
+	if !s.wantsOutputFormat(GrepableOutput{}) {
+		t.Error("expected GrepableOutput to be wanted")
+	}
+	if !s.wantsOutputFormat(JSONOutput{}) {
+		t.Error("expected JSONOutput to be wanted")
+	}
+	if s.wantsOutputFormat(NormalOutput{}) {
+		t.Error("expected NormalOutput not to be wanted")
+	}
+}
+
+func TestRunWithOutputFormats(t *testing.T) {
+	script := fakeNmapOutputFormats(t, true, true)
+
+	s, err := New(
+		WithBinaryPath(script),
+		WithTarget("10.0.0.1"),
+		WithOutputFormats(GrepableOutput{}, NormalOutput{}, JSONOutput{}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	result, err := s.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(result.Hosts) != 1 {
+		t.Fatalf("expected 1 host parsed from XML, got %d", len(result.Hosts))
+	}
+
+	if result.Grepable == nil || len(result.Grepable.Hosts) != 1 {
+		t.Fatalf("expected Grepable to be populated with 1 host, got %#v", result.Grepable)
+	}
+
+	if result.Normal != "Nmap scan report for 10.0.0.1\n" {
+		t.Errorf("unexpected Normal output: %q", result.Normal)
+	}
+
+	if len(result.JSON) == 0 {
+		t.Error("expected JSON to be populated")
+	}
+}
+
+func TestRunWithOutputFormatsOnlyRequestedFormats(t *testing.T) {
+	script := fakeNmapOutputFormats(t, false, false)
+
+	s, err := New(
+		WithBinaryPath(script),
+		WithTarget("10.0.0.1"),
+		WithOutputFormats(XMLOutput{}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	result, err := s.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if result.Grepable != nil {
+		t.Errorf("expected Grepable to be left unset, got %#v", result.Grepable)
+	}
+	if result.Normal != "" {
+		t.Errorf("expected Normal to be left unset, got %q", result.Normal)
+	}
+	if result.JSON != nil {
+		t.Errorf("expected JSON to be left unset, got %s", result.JSON)
+	}
+}
+
+func TestRunWithOutputFormatsMissingFile(t *testing.T) {
+	script := fakeNmapOutputFormats(t, false, false)
+
+	s, err := New(
+		WithBinaryPath(script),
+		WithTarget("10.0.0.1"),
+		WithOutputFormats(GrepableOutput{}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err := s.Run(); err == nil {
+		t.Error("expected an error when the requested .gnmap file was never written, got nil")
+	}
+}
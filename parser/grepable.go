@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Grepable is the result of parsing nmap's grepable output (-oG).
+type Grepable struct {
+	Hosts []GrepableHost
+}
+
+// GrepableHost is a single host line decoded from grepable output.
+type GrepableHost struct {
+	Address  string
+	Hostname string
+	Status   string
+	Ports    []GrepablePort
+}
+
+// GrepablePort is a single port entry within a grepable host's "Ports:"
+// field.
+type GrepablePort struct {
+	Port     int
+	Protocol string
+	State    string
+	Service  string
+}
+
+// ParseGrepable parses nmap's grepable output format line by line,
+// skipping the comment lines nmap uses to mark the start and end of a
+// scan.
+func ParseGrepable(r io.Reader) (*Grepable, error) {
+	result := &Grepable{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "Host: ") {
+			continue
+		}
+
+		host := parseHostField(fields[0])
+		for _, field := range fields[1:] {
+			switch {
+			case strings.HasPrefix(field, "Status: "):
+				host.Status = strings.TrimPrefix(field, "Status: ")
+			case strings.HasPrefix(field, "Ports: "):
+				host.Ports = parsePortsField(strings.TrimPrefix(field, "Ports: "))
+			}
+		}
+
+		result.Hosts = append(result.Hosts, host)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// parseHostField parses the "Host: <address> (<hostname>)" field that
+// starts every host line.
+func parseHostField(field string) GrepableHost {
+	field = strings.TrimPrefix(field, "Host: ")
+
+	parts := strings.SplitN(field, " ", 2)
+
+	host := GrepableHost{Address: parts[0]}
+	if len(parts) == 2 {
+		host.Hostname = strings.Trim(parts[1], "()")
+	}
+
+	return host
+}
+
+// parsePortsField parses a comma-separated "Ports:" field, whose entries
+// look like "22/open/tcp//ssh///".
+func parsePortsField(field string) []GrepablePort {
+	var ports []GrepablePort
+
+	for _, entry := range strings.Split(field, ", ") {
+		segments := strings.Split(entry, "/")
+		if len(segments) < 5 {
+			continue
+		}
+
+		port, err := strconv.Atoi(segments[0])
+		if err != nil {
+			continue
+		}
+
+		ports = append(ports, GrepablePort{
+			Port:     port,
+			State:    segments[1],
+			Protocol: segments[2],
+			Service:  segments[4],
+		})
+	}
+
+	return ports
+}
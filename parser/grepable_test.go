@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGrepable(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		expected    *Grepable
+	}{
+		{
+			description: "single host with a named port and a hostname",
+			input: `# Nmap 7.94 scan initiated as: nmap -oG - localhost
+Host: 127.0.0.1 (localhost)	Status: Up
+Host: 127.0.0.1 (localhost)	Ports: 22/open/tcp//ssh///, 80/closed/tcp//http///	Ignored State:
+# Nmap done at Thu Jan  1 00:00:00 1970 -- 1 IP address (1 host up) scanned in 0.01 seconds
+`,
+			expected: &Grepable{
+				Hosts: []GrepableHost{
+					{Address: "127.0.0.1", Hostname: "localhost", Status: "Up"},
+					{
+						Address:  "127.0.0.1",
+						Hostname: "localhost",
+						Ports: []GrepablePort{
+							{Port: 22, Protocol: "tcp", State: "open", Service: "ssh"},
+							{Port: 80, Protocol: "tcp", State: "closed", Service: "http"},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "host without a hostname",
+			input:       "Host: 10.0.0.1 ()\tStatus: Down\n",
+			expected: &Grepable{
+				Hosts: []GrepableHost{
+					{Address: "10.0.0.1", Status: "Down"},
+				},
+			},
+		},
+		{
+			description: "malformed port entries are skipped",
+			input:       "Host: 10.0.0.1 ()\tPorts: not-a-port, 80/open/tcp//http///\n",
+			expected: &Grepable{
+				Hosts: []GrepableHost{
+					{
+						Address: "10.0.0.1",
+						Ports: []GrepablePort{
+							{Port: 80, Protocol: "tcp", State: "open", Service: "http"},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "blank and comment-only input yields no hosts",
+			input:       "\n# just a comment\n\n",
+			expected:    &Grepable{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := ParseGrepable(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %#v got %#v", test.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+// Package parser implements readers for the output formats nmap can
+// produce alongside its default XML, so that Scanner can expose them on
+// Run without every caller having to shell out to a parsing library of
+// their own.
+package parser
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// XML decodes nmap's XML output (-oX) from r into v, which is typically
+// a *nmap.Run.
+func XML(r io.Reader, v interface{}) error {
+	return xml.NewDecoder(r).Decode(v)
+}
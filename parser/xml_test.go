@@ -0,0 +1,30 @@
+package parser
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+type testRun struct {
+	XMLName xml.Name `xml:"nmaprun"`
+	Scanner string   `xml:"scanner,attr"`
+}
+
+func TestXML(t *testing.T) {
+	var result testRun
+	if err := XML(strings.NewReader(`<nmaprun scanner="nmap"></nmaprun>`), &result); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if result.Scanner != "nmap" {
+		t.Errorf("expected scanner %q got %q", "nmap", result.Scanner)
+	}
+}
+
+func TestXMLPropagatesDecodeErrors(t *testing.T) {
+	var result testRun
+	if err := XML(strings.NewReader("<not-closed>"), &result); err == nil {
+		t.Error("expected an error for malformed XML, got nil")
+	}
+}
@@ -0,0 +1,234 @@
+package nmap
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// defaultResumeTTL is how long a cached Run is considered fresh enough
+// to short-circuit a re-run, when WithResumeTTL is not used to override
+// it.
+const defaultResumeTTL = 24 * time.Hour
+
+// ErrNoResumeFile is returned by Resume when no path was set via
+// WithResumeFile.
+var ErrNoResumeFile = errors.New("nmap: WithResumeFile must be set to use Resume")
+
+// resumeState is the JSON envelope persisted to the file given to
+// WithResumeFile.
+type resumeState struct {
+	Fingerprint string    `json:"fingerprint"`
+	Argv        []string  `json:"argv"`
+	Run         *Run      `json:"run"`
+	CachedAt    time.Time `json:"cached_at"`
+
+	// Interrupted marks that the last attempt timed out or was
+	// cancelled before nmap finished, so the next Resume call should
+	// continue it with --resume rather than starting a fresh scan.
+	Interrupted bool `json:"interrupted"`
+}
+
+// WithResumeFile tells Resume where to persist the assembled argv and
+// the last successfully parsed Run, so that repeated invocations with
+// the same configuration can reuse a recent result instead of
+// re-scanning, and interrupted scans can continue where they left off.
+func WithResumeFile(path string) func(*Scanner) {
+	return func(s *Scanner) {
+		s.resumeFile = path
+	}
+}
+
+// WithResumeTTL overrides how long a cached Run is considered fresh
+// enough for Resume to return without re-scanning. It defaults to 24h;
+// pass 0 to never reuse a cached Run.
+func WithResumeTTL(ttl time.Duration) func(*Scanner) {
+	return func(s *Scanner) {
+		s.resumeTTL = ttl
+		s.resumeTTLSet = true
+	}
+}
+
+// Resume runs the scan, but, when WithResumeFile was used, first checks
+// whether a cached Run from an identical argv and target set is still
+// within its TTL, returning it without invoking nmap at all. Failing
+// that, if the previous attempt with this same configuration timed out
+// or was cancelled, it continues that scan via nmap's --resume against
+// the normal-format log left behind, rather than starting over. Otherwise
+// it runs a fresh scan. The outcome is persisted for the next call,
+// making it safe for orchestrators to invoke the same scan repeatedly,
+// including after a context cancellation.
+func (s *Scanner) Resume() (*Run, error) {
+	if s.resumeFile == "" {
+		return nil, ErrNoResumeFile
+	}
+
+	if cached, ok := s.cachedRun(); ok {
+		return cached, nil
+	}
+
+	normalLog := s.resumeFile + ".normal"
+
+	if state, ok := s.loadResumeState(); ok && state.Interrupted && state.Fingerprint == s.fingerprint() {
+		return s.resumeInterruptedScan(normalLog)
+	}
+
+	return s.runFreshScan(normalLog)
+}
+
+// runFreshScan runs a new scan, additionally writing nmap's normal-format
+// log so a later call can continue it with --resume if it's interrupted.
+func (s *Scanner) runFreshScan(normalLog string) (*Run, error) {
+	originalArgs := s.args
+	WithNmapOutput(normalLog)(s)
+	result, err := s.Run()
+	s.args = originalArgs
+
+	if err != nil {
+		if errors.Is(err, ErrScanTimeout) {
+			if saveErr := s.saveResumeState(nil, true); saveErr != nil {
+				return nil, saveErr
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.saveResumeState(result, false); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// resumeInterruptedScan continues a previously interrupted scan via
+// nmap's --resume. nmap reconstructs the original target specification
+// and scan type from the log itself, so this bypasses commandWithOutput
+// and the scanner's own args entirely, passing only --resume and the
+// -oX flag that resumeCommand adds.
+func (s *Scanner) resumeInterruptedScan(normalLog string) (*Run, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := s.resumeCommand(normalLog)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if s.ctx != nil && s.ctx.Err() != nil {
+			if saveErr := s.saveResumeState(nil, true); saveErr != nil {
+				return nil, saveErr
+			}
+			return nil, ErrScanTimeout
+		}
+		return nil, fmt.Errorf("nmap scan failed: %s", err)
+	}
+
+	if stderr.Len() > 0 {
+		return nil, errors.New(stderr.String())
+	}
+
+	var result Run
+	if err := xml.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, err
+	}
+
+	result.Hosts = s.filterHosts(result.Hosts)
+	for i := range result.Hosts {
+		result.Hosts[i].Ports = s.filterPorts(result.Hosts[i].Ports)
+	}
+
+	if err := s.saveResumeState(&result, false); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// resumeCommand builds the exec.Cmd that continues an interrupted scan.
+// nmap's --resume fixes the target specification and scan type to
+// whatever is in the log, but the output format can still be requested
+// fresh, so -oX is added to get back parseable XML on stdout.
+func (s *Scanner) resumeCommand(normalLog string) *exec.Cmd {
+	if s.ctx != nil {
+		return exec.CommandContext(s.ctx, s.binaryPath, "--resume", normalLog, "-oX", "-")
+	}
+	return exec.Command(s.binaryPath, "--resume", normalLog, "-oX", "-")
+}
+
+// fingerprint identifies the current argv and target set, so Resume can
+// tell whether a cached or interrupted Run was produced by the same
+// configuration.
+func (s *Scanner) fingerprint() string {
+	hash := sha256.New()
+	for _, arg := range s.args {
+		hash.Write([]byte(arg))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// loadResumeState reads and decodes the resume file, if any.
+func (s *Scanner) loadResumeState() (*resumeState, bool) {
+	data, err := os.ReadFile(s.resumeFile)
+	if err != nil {
+		return nil, false
+	}
+
+	var state resumeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, false
+	}
+
+	return &state, true
+}
+
+// cachedRun returns the resume file's Run if it matches the current
+// fingerprint, is still within the TTL, and isn't a marker for an
+// interrupted scan.
+func (s *Scanner) cachedRun() (*Run, bool) {
+	state, ok := s.loadResumeState()
+	if !ok || state.Interrupted || state.Run == nil {
+		return nil, false
+	}
+
+	if state.Fingerprint != s.fingerprint() {
+		return nil, false
+	}
+
+	ttl := defaultResumeTTL
+	if s.resumeTTLSet {
+		ttl = s.resumeTTL
+	}
+
+	if time.Since(state.CachedAt) > ttl {
+		return nil, false
+	}
+
+	return state.Run, true
+}
+
+// saveResumeState persists the current argv and outcome to the resume
+// file, so a future call to Resume can reuse, continue, or restart it.
+func (s *Scanner) saveResumeState(result *Run, interrupted bool) error {
+	state := resumeState{
+		Fingerprint: s.fingerprint(),
+		Argv:        append([]string{}, s.args...),
+		Run:         result,
+		CachedAt:    time.Now(),
+		Interrupted: interrupted,
+	}
+
+	data, err := json.MarshalIndent(&state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.resumeFile, data, 0o644)
+}
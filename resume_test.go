@@ -0,0 +1,217 @@
+package nmap
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResumeCachedRun(t *testing.T) {
+	resumeFile := filepath.Join(t.TempDir(), "resume.json")
+
+	s, err := New(WithTarget("localhost"), WithResumeFile(resumeFile))
+	if err != nil {
+		panic(err)
+	}
+
+	want := &Run{Args: "nmap -oX - localhost", Scanner: "nmap"}
+
+	writeResumeState(t, resumeFile, resumeState{
+		Fingerprint: s.fingerprint(),
+		Argv:        s.args,
+		Run:         want,
+		CachedAt:    time.Now(),
+	})
+
+	got, ok := s.cachedRun()
+	if !ok {
+		t.Fatal("expected a cache hit, got a miss")
+	}
+
+	if got.Args != want.Args {
+		t.Errorf("expected cached args %q got %q", want.Args, got.Args)
+	}
+}
+
+func TestResumeCacheMissOnFingerprintChange(t *testing.T) {
+	resumeFile := filepath.Join(t.TempDir(), "resume.json")
+
+	s, err := New(WithTarget("localhost"), WithResumeFile(resumeFile))
+	if err != nil {
+		panic(err)
+	}
+
+	writeResumeState(t, resumeFile, resumeState{
+		Fingerprint: "stale-fingerprint",
+		Run:         &Run{Args: "nmap -oX - localhost"},
+		CachedAt:    time.Now(),
+	})
+
+	if _, ok := s.cachedRun(); ok {
+		t.Error("expected a cache miss for a different fingerprint, got a hit")
+	}
+}
+
+func TestResumeCacheMissAfterTTL(t *testing.T) {
+	resumeFile := filepath.Join(t.TempDir(), "resume.json")
+
+	s, err := New(
+		WithTarget("localhost"),
+		WithResumeFile(resumeFile),
+		WithResumeTTL(time.Minute),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	writeResumeState(t, resumeFile, resumeState{
+		Fingerprint: s.fingerprint(),
+		Run:         &Run{Args: "nmap -oX - localhost"},
+		CachedAt:    time.Now().Add(-time.Hour),
+	})
+
+	if _, ok := s.cachedRun(); ok {
+		t.Error("expected a cache miss for an expired entry, got a hit")
+	}
+}
+
+func TestResumeZeroTTLDisablesCache(t *testing.T) {
+	resumeFile := filepath.Join(t.TempDir(), "resume.json")
+
+	s, err := New(
+		WithTarget("localhost"),
+		WithResumeFile(resumeFile),
+		WithResumeTTL(0),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	writeResumeState(t, resumeFile, resumeState{
+		Fingerprint: s.fingerprint(),
+		Run:         &Run{Args: "nmap -oX - localhost"},
+		CachedAt:    time.Now(),
+	})
+
+	if _, ok := s.cachedRun(); ok {
+		t.Error("expected a cache miss when WithResumeTTL(0) is set, got a hit")
+	}
+}
+
+func TestResumeDoesNotMutateArgsPermanently(t *testing.T) {
+	resumeFile := filepath.Join(t.TempDir(), "resume.json")
+
+	s, err := New(WithTarget("localhost"), WithResumeFile(resumeFile))
+	if err != nil {
+		panic(err)
+	}
+
+	original := append([]string{}, s.args...)
+
+	// Resume is expected to fail here, since there is no real nmap binary
+	// in the test environment. What matters is that it doesn't leave
+	// s.args mutated behind it.
+	if _, err := s.Resume(); err == nil {
+		t.Fatal("expected Resume to fail without a real nmap binary")
+	}
+
+	if !reflect.DeepEqual(s.args, original) {
+		t.Fatalf("expected args to be restored to %v, got %v", original, s.args)
+	}
+}
+
+func TestResumeCacheMissOnInterruptedState(t *testing.T) {
+	resumeFile := filepath.Join(t.TempDir(), "resume.json")
+
+	s, err := New(WithTarget("localhost"), WithResumeFile(resumeFile))
+	if err != nil {
+		panic(err)
+	}
+
+	writeResumeState(t, resumeFile, resumeState{
+		Fingerprint: s.fingerprint(),
+		Run:         &Run{Args: "nmap -oX - localhost"},
+		CachedAt:    time.Now(),
+		Interrupted: true,
+	})
+
+	if _, ok := s.cachedRun(); ok {
+		t.Error("expected a cache miss for an interrupted-scan marker, got a hit")
+	}
+}
+
+func TestResumeContinuesInterruptedScan(t *testing.T) {
+	resumeFile := filepath.Join(t.TempDir(), "resume.json")
+
+	script := fakeNmap(t, `#!/bin/sh
+cat <<'XML'
+<?xml version="1.0"?>
+<nmaprun>
+<host>
+<status state="up"/>
+<address addr="10.0.0.1" addrtype="ipv4"/>
+<ports><port protocol="tcp" portid="80"><state state="open"/></port></ports>
+</host>
+</nmaprun>
+XML
+`)
+
+	s, err := New(WithBinaryPath(script), WithTarget("10.0.0.1"), WithResumeFile(resumeFile))
+	if err != nil {
+		panic(err)
+	}
+
+	writeResumeState(t, resumeFile, resumeState{
+		Fingerprint: s.fingerprint(),
+		Run:         nil,
+		CachedAt:    time.Now(),
+		Interrupted: true,
+	})
+
+	result, err := s.Resume()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(result.Hosts) != 1 {
+		t.Fatalf("expected 1 host decoded from the replayed scan, got %d", len(result.Hosts))
+	}
+	if result.Hosts[0].Addresses[0].Addr != "10.0.0.1" {
+		t.Errorf("expected host 10.0.0.1, got %s", result.Hosts[0].Addresses[0].Addr)
+	}
+
+	state, ok := s.loadResumeState()
+	if !ok {
+		t.Fatal("expected a resume state to have been persisted")
+	}
+	if state.Interrupted {
+		t.Error("expected the persisted state to no longer be marked interrupted")
+	}
+	if state.Run == nil || len(state.Run.Hosts) != 1 {
+		t.Errorf("expected the persisted state to cache the decoded hosts, got %#v", state.Run)
+	}
+
+	cached, ok := s.cachedRun()
+	if !ok {
+		t.Fatal("expected the decoded result to now be served from cache")
+	}
+	if len(cached.Hosts) != 1 {
+		t.Errorf("expected the cached result to carry the decoded host, got %d hosts", len(cached.Hosts))
+	}
+}
+
+func writeResumeState(t *testing.T, path string, state resumeState) {
+	t.Helper()
+
+	data, err := json.Marshal(&state)
+	if err != nil {
+		t.Fatalf("failed to marshal resume state: %s", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("failed to write resume state: %s", err)
+	}
+}
@@ -0,0 +1,202 @@
+package nmap
+
+import (
+	"encoding/xml"
+
+	"github.com/ileansys/nmap/parser"
+)
+
+// Run is the top-level XML element produced by nmap when invoked with
+// -oX. It is the root of the result tree returned by Scanner.Run.
+type Run struct {
+	XMLName          xml.Name  `xml:"nmaprun"`
+	Args             string    `xml:"args,attr"`
+	ProfileName      string    `xml:"profile_name,attr"`
+	Scanner          string    `xml:"scanner,attr"`
+	StartStr         string    `xml:"startstr,attr"`
+	Version          string    `xml:"version,attr"`
+	XMLOutputVersion string    `xml:"xmloutputversion,attr"`
+	ScanInfo         ScanInfo  `xml:"scaninfo"`
+	Verbose          Verbose   `xml:"verbose"`
+	Debugging        Debugging `xml:"debugging"`
+	TaskBegin        []Task    `xml:"taskbegin"`
+	TaskProgress     []Task    `xml:"taskprogress"`
+	TaskEnd          []Task    `xml:"taskend"`
+	PreScripts       []Script  `xml:"prescript>script"`
+	PostScripts      []Script  `xml:"postscript>script"`
+	Hosts            []Host    `xml:"host"`
+	RunStats         RunStats  `xml:"runstats"`
+
+	// Grepable, Normal and JSON are only populated when the matching
+	// OutputFormat was requested via WithOutputFormats.
+	Grepable *parser.Grepable `xml:"-" json:"-"`
+	Normal   string           `xml:"-" json:"-"`
+	JSON     []byte           `xml:"-" json:"-"`
+}
+
+// ScanInfo describes one of the scan types nmap ran as part of this Run.
+type ScanInfo struct {
+	Type        string `xml:"type,attr"`
+	Protocol    string `xml:"protocol,attr"`
+	NumServices int    `xml:"numservices,attr"`
+	Services    string `xml:"services,attr"`
+}
+
+// Verbose holds the verbosity level nmap was run with.
+type Verbose struct {
+	Level int `xml:"level,attr"`
+}
+
+// Debugging holds the debugging level nmap was run with.
+type Debugging struct {
+	Level int `xml:"level,attr"`
+}
+
+// Task describes a taskbegin, taskprogress or taskend element, used by
+// nmap to report progress on long-running phases of a scan.
+type Task struct {
+	Task      string `xml:"task,attr"`
+	Time      string `xml:"time,attr"`
+	ExtraInfo string `xml:"extrainfo,attr"`
+	Percent   string `xml:"percent,attr"`
+	Remaining string `xml:"remaining,attr"`
+	Etc       string `xml:"etc,attr"`
+}
+
+// Script is the result of an NSE script run against a host or port.
+type Script struct {
+	ID       string    `xml:"id,attr"`
+	Output   string    `xml:"output,attr"`
+	Elements []Element `xml:"elem"`
+}
+
+// Element is a single key/value pair nested within a Script's structured
+// output.
+type Element struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Host represents a single host that nmap reported on, alive or not.
+type Host struct {
+	StartTime string     `xml:"starttime,attr"`
+	EndTime   string     `xml:"endtime,attr"`
+	Status    Status     `xml:"status"`
+	Addresses []Address  `xml:"address"`
+	Hostnames []Hostname `xml:"hostnames>hostname"`
+	Ports     []Port     `xml:"ports>port"`
+	Os        Os         `xml:"os"`
+	Times     Times      `xml:"times"`
+	Trace     Trace      `xml:"trace"`
+}
+
+// Status describes whether a host is up, down or unknown, and why nmap
+// believes so.
+type Status struct {
+	State     string `xml:"state,attr"`
+	Reason    string `xml:"reason,attr"`
+	ReasonTTL string `xml:"reason_ttl,attr"`
+}
+
+// Address is one of the (possibly several) addresses nmap resolved for a
+// host, e.g. an IPv4 address and the MAC address behind it.
+type Address struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+	Vendor   string `xml:"vendor,attr"`
+}
+
+// Hostname is a single name nmap resolved for a host, along with how it
+// was obtained (e.g. "user", "PTR").
+type Hostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// Port is a single port nmap probed on a host.
+type Port struct {
+	Protocol string   `xml:"protocol,attr"`
+	ID       uint16   `xml:"portid,attr"`
+	State    State    `xml:"state"`
+	Owner    Owner    `xml:"owner"`
+	Service  Service  `xml:"service"`
+	Scripts  []Script `xml:"script"`
+}
+
+// State describes whether a Port is open, closed or filtered, and why.
+type State struct {
+	State     string `xml:"state,attr"`
+	Reason    string `xml:"reason,attr"`
+	ReasonTTL string `xml:"reason_ttl,attr"`
+}
+
+// Owner is the process reported to own a Port, when --privileged
+// information is available.
+type Owner struct {
+	Name string `xml:"name,attr"`
+}
+
+// Service is the result of nmap's version detection (-sV) for a Port.
+type Service struct {
+	Name       string `xml:"name,attr"`
+	Product    string `xml:"product,attr"`
+	Version    string `xml:"version,attr"`
+	ExtraInfo  string `xml:"extrainfo,attr"`
+	Method     string `xml:"method,attr"`
+	Confidence string `xml:"conf,attr"`
+}
+
+// Os holds the results of nmap's OS detection (-O) for a host.
+type Os struct {
+	Matches []OsMatch `xml:"osmatch"`
+}
+
+// OsMatch is a single candidate OS nmap matched against a host's
+// fingerprint, ranked by Accuracy.
+type OsMatch struct {
+	Name     string `xml:"name,attr"`
+	Accuracy string `xml:"accuracy,attr"`
+}
+
+// Times holds nmap's computed round-trip-time statistics for a host.
+type Times struct {
+	SRTT string `xml:"srtt,attr"`
+	RTT  string `xml:"rttvar,attr"`
+	To   string `xml:"to,attr"`
+}
+
+// Trace holds the hops nmap recorded on the way to a host (--traceroute).
+type Trace struct {
+	Hops []Hop `xml:"hop"`
+}
+
+// Hop is a single router hop recorded by a traceroute.
+type Hop struct {
+	TTL    string `xml:"ttl,attr"`
+	IPAddr string `xml:"ipaddr,attr"`
+	RTT    string `xml:"rtt,attr"`
+	Host   string `xml:"host,attr"`
+}
+
+// RunStats summarizes the outcome of a Run, such as how many hosts were
+// found up and how long the scan took.
+type RunStats struct {
+	Finished Finished `xml:"finished"`
+	Hosts    HostStat `xml:"hosts"`
+}
+
+// Finished reports when a scan finished and how long it took.
+type Finished struct {
+	Time    string `xml:"time,attr"`
+	TimeStr string `xml:"timestr,attr"`
+	Elapsed string `xml:"elapsed,attr"`
+	Summary string `xml:"summary,attr"`
+	Exit    string `xml:"exit,attr"`
+}
+
+// HostStat summarizes how many hosts were scanned, up and down.
+type HostStat struct {
+	Up    int `xml:"up,attr"`
+	Down  int `xml:"down,attr"`
+	Total int `xml:"total,attr"`
+}
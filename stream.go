@@ -0,0 +1,166 @@
+package nmap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// Event is implemented by every value sent on the channel returned by
+// RunAsync, letting callers react to host and port discoveries as they
+// are parsed instead of waiting for the scan to finish.
+type Event interface {
+	event()
+}
+
+// HostUpEvent is emitted as soon as a host is decoded from nmap's XML
+// output and reported as up.
+type HostUpEvent struct {
+	Host Host
+}
+
+func (HostUpEvent) event() {}
+
+// HostDownEvent is emitted as soon as a host is decoded from nmap's XML
+// output and reported as down.
+type HostDownEvent struct {
+	Host Host
+}
+
+func (HostDownEvent) event() {}
+
+// PortOpenEvent is emitted for every open port found on a host.
+type PortOpenEvent struct {
+	Host Host
+	Port Port
+}
+
+func (PortOpenEvent) event() {}
+
+// TaskProgressEvent is emitted every time nmap reports progress on a
+// long-running phase of the scan. It relies on --stats-every, which
+// RunAsync adds to the command line automatically.
+type TaskProgressEvent struct {
+	Task Task
+}
+
+func (TaskProgressEvent) event() {}
+
+// ScanCompleteEvent is always the last event sent on the channel
+// returned by RunAsync. Run holds every host seen so far, filtered the
+// same way Run's result is. Err is ErrScanTimeout when the context set
+// via WithContext is done, otherwise the error from nmap's exit status
+// or its stderr output; XML that fails to decode is not reported in Err.
+type ScanCompleteEvent struct {
+	Run *Run
+	Err error
+}
+
+func (ScanCompleteEvent) event() {}
+
+// RunAsync starts nmap and streams host and port discoveries as they are
+// decoded from its XML output, instead of blocking until the whole scan
+// finishes like Run does. This is useful for long-running scans against
+// large target ranges, where waiting for completion means waiting hours
+// to see the first result.
+//
+// The returned channel is closed once the scan finishes, its context
+// (set via WithContext) is canceled, or the nmap process dies; the last
+// event sent on it is always a ScanCompleteEvent. Filters registered via
+// WithFilterHost and WithFilterPort apply to streamed events exactly as
+// they do to Run's result.
+func (s *Scanner) RunAsync() (<-chan Event, error) {
+	cmd := s.commandWithOutput([]string{"-oX", "-"}, "--stats-every", "1s")
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("nmap scan failed: %s", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("nmap scan failed: %s", err)
+	}
+
+	events := make(chan Event)
+
+	go s.stream(cmd, stdout, &stderr, events)
+
+	return events, nil
+}
+
+// stream decodes stdout incrementally, emitting events as host, ports
+// and taskprogress elements are fully read, then waits for the process
+// to exit and sends the final ScanCompleteEvent.
+func (s *Scanner) stream(cmd *exec.Cmd, stdout io.ReadCloser, stderr *bytes.Buffer, events chan<- Event) {
+	defer close(events)
+
+	run := &Run{}
+	decoder := xml.NewDecoder(stdout)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "host":
+			var host Host
+			if err := decoder.DecodeElement(&host, &start); err != nil {
+				continue
+			}
+
+			if !s.hostMatches(host) {
+				continue
+			}
+
+			host.Ports = s.filterPorts(host.Ports)
+			run.Hosts = append(run.Hosts, host)
+
+			if host.Status.State == "up" {
+				events <- HostUpEvent{Host: host}
+			} else {
+				events <- HostDownEvent{Host: host}
+			}
+
+			for _, port := range host.Ports {
+				if port.State.State == "open" {
+					events <- PortOpenEvent{Host: host, Port: port}
+				}
+			}
+		case "taskprogress":
+			var task Task
+			if err := decoder.DecodeElement(&task, &start); err != nil {
+				continue
+			}
+
+			events <- TaskProgressEvent{Task: task}
+		}
+	}
+
+	err := cmd.Wait()
+
+	switch {
+	case s.ctx != nil && s.ctx.Err() != nil:
+		err = ErrScanTimeout
+	case err != nil:
+		err = fmt.Errorf("nmap scan failed: %s", err)
+	case stderr.Len() > 0:
+		err = errors.New(stderr.String())
+	default:
+		err = nil
+	}
+
+	events <- ScanCompleteEvent{Run: run, Err: err}
+}
@@ -0,0 +1,218 @@
+package nmap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeNmap writes the given shell script to an executable file in a temp
+// dir, standing in for the real nmap binary (the scripts passed in print
+// canned XML to stdout) so
+// RunAsync's decoding, filtering and completion logic can be exercised
+// without depending on a real scan.
+func fakeNmap(t *testing.T, script string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "fake-nmap.sh")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake nmap script: %s", err)
+	}
+
+	return path
+}
+
+func TestRunAsync(t *testing.T) {
+	script := fakeNmap(t, `#!/bin/sh
+cat <<'XML'
+<?xml version="1.0"?>
+<nmaprun>
+<taskprogress task="SYN Stealth Scan" percent="50.00"/>
+<host>
+<status state="up"/>
+<address addr="10.0.0.1" addrtype="ipv4"/>
+<ports><port protocol="tcp" portid="80"><state state="open"/></port></ports>
+</host>
+<host>
+<status state="down"/>
+<address addr="10.0.0.2" addrtype="ipv4"/>
+</host>
+</nmaprun>
+XML
+`)
+
+	s, err := New(WithBinaryPath(script), WithTarget("10.0.0.0/24"))
+	if err != nil {
+		panic(err)
+	}
+
+	events, err := s.RunAsync()
+	if err != nil {
+		t.Fatalf("RunAsync failed: %s", err)
+	}
+
+	var got []Event
+	for event := range drain(t, events) {
+		got = append(got, event)
+	}
+
+	if len(got) != 5 {
+		t.Fatalf("expected 5 events, got %d: %#v", len(got), got)
+	}
+
+	if _, ok := got[0].(TaskProgressEvent); !ok {
+		t.Errorf("expected event 0 to be a TaskProgressEvent, got %T", got[0])
+	}
+
+	up, ok := got[1].(HostUpEvent)
+	if !ok {
+		t.Fatalf("expected event 1 to be a HostUpEvent, got %T", got[1])
+	}
+	if up.Host.Addresses[0].Addr != "10.0.0.1" {
+		t.Errorf("expected host 10.0.0.1, got %s", up.Host.Addresses[0].Addr)
+	}
+
+	port, ok := got[2].(PortOpenEvent)
+	if !ok {
+		t.Fatalf("expected event 2 to be a PortOpenEvent, got %T", got[2])
+	}
+	if port.Port.ID != 80 {
+		t.Errorf("expected port 80, got %d", port.Port.ID)
+	}
+
+	if _, ok := got[3].(HostDownEvent); !ok {
+		t.Errorf("expected event 3 to be a HostDownEvent, got %T", got[3])
+	}
+
+	down, ok := got[4].(ScanCompleteEvent)
+	if !ok {
+		t.Fatalf("expected the final event to be a ScanCompleteEvent, got %T", got[4])
+	}
+	if down.Err != nil {
+		t.Errorf("expected a nil error, got %s", down.Err)
+	}
+	if len(down.Run.Hosts) != 2 {
+		t.Errorf("expected 2 hosts in the final Run, got %d", len(down.Run.Hosts))
+	}
+}
+
+func TestRunAsyncAppliesFilters(t *testing.T) {
+	script := fakeNmap(t, `#!/bin/sh
+cat <<'XML'
+<?xml version="1.0"?>
+<nmaprun>
+<host>
+<status state="up"/>
+<address addr="10.0.0.1" addrtype="ipv4"/>
+<ports><port protocol="tcp" portid="80"><state state="open"/></port></ports>
+</host>
+<host>
+<status state="up"/>
+<address addr="10.0.0.2" addrtype="ipv4"/>
+<ports><port protocol="tcp" portid="443"><state state="open"/></port></ports>
+</host>
+</nmaprun>
+XML
+`)
+
+	s, err := New(
+		WithBinaryPath(script),
+		WithTarget("10.0.0.0/24"),
+		WithFilterHost(func(h Host) bool {
+			return h.Addresses[0].Addr == "10.0.0.2"
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	events, err := s.RunAsync()
+	if err != nil {
+		t.Fatalf("RunAsync failed: %s", err)
+	}
+
+	var hostEvents int
+	var complete ScanCompleteEvent
+	for event := range drain(t, events) {
+		switch e := event.(type) {
+		case HostUpEvent:
+			hostEvents++
+			if e.Host.Addresses[0].Addr != "10.0.0.2" {
+				t.Errorf("expected the filtered-out host 10.0.0.1 not to be emitted, got %s", e.Host.Addresses[0].Addr)
+			}
+		case ScanCompleteEvent:
+			complete = e
+		}
+	}
+
+	if hostEvents != 1 {
+		t.Errorf("expected exactly 1 HostUpEvent after filtering, got %d", hostEvents)
+	}
+	if len(complete.Run.Hosts) != 1 {
+		t.Errorf("expected exactly 1 host in the final Run after filtering, got %d", len(complete.Run.Hosts))
+	}
+}
+
+func TestRunAsyncContextCancellation(t *testing.T) {
+	script := fakeNmap(t, `#!/bin/sh
+cat <<'XML'
+<?xml version="1.0"?>
+<nmaprun>
+<host><status state="up"/><address addr="10.0.0.1" addrtype="ipv4"/></host>
+XML
+exec sleep 5
+`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	s, err := New(WithBinaryPath(script), WithTarget("10.0.0.0/24"), WithContext(ctx))
+	if err != nil {
+		panic(err)
+	}
+
+	events, err := s.RunAsync()
+	if err != nil {
+		t.Fatalf("RunAsync failed: %s", err)
+	}
+
+	var complete ScanCompleteEvent
+	for event := range drain(t, events) {
+		if e, ok := event.(ScanCompleteEvent); ok {
+			complete = e
+		}
+	}
+
+	if complete.Err != ErrScanTimeout {
+		t.Errorf("expected ErrScanTimeout, got %v", complete.Err)
+	}
+}
+
+// drain relays events onto a fresh channel, failing the test instead of
+// hanging forever if the source channel never closes.
+func drain(t *testing.T, events <-chan Event) <-chan Event {
+	t.Helper()
+
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				out <- event
+			case <-time.After(5 * time.Second):
+				t.Error("timed out waiting for RunAsync to close its event channel")
+				return
+			}
+		}
+	}()
+
+	return out
+}